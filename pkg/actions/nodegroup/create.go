@@ -0,0 +1,30 @@
+// Package nodegroup implements the `eksctl create/delete nodegroup` actions.
+package nodegroup
+
+import (
+	"fmt"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha4"
+	"github.com/weaveworks/eksctl/pkg/cfn/builder"
+)
+
+// Manager creates and deletes nodegroup CloudFormation stacks
+type Manager struct {
+	provider api.ClusterProvider
+	cfg      *api.ClusterConfig
+}
+
+// NewManager returns a nodegroup Manager for the given cluster
+func NewManager(provider api.ClusterProvider, cfg *api.ClusterConfig) *Manager {
+	return &Manager{provider: provider, cfg: cfg}
+}
+
+// Create renders the CloudFormation stack for every nodegroup currently in the cluster config,
+// sharing a single stack across all of them, and returns its JSON; it does not deploy the stack.
+func (m *Manager) Create(clusterStackName string) ([]byte, error) {
+	rs := builder.NewNodeGroupResourceSet(m.provider, m.cfg, clusterStackName, m.cfg.NodeGroups)
+	if err := rs.AddAllResources(); err != nil {
+		return nil, fmt.Errorf("building nodegroup stack: %w", err)
+	}
+	return rs.RenderJSON()
+}
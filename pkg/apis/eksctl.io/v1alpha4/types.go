@@ -0,0 +1,180 @@
+// Package v1alpha4 holds the (internal, non-CRD) Go types used to describe a cluster and its
+// nodegroups as they are translated into CloudFormation by pkg/cfn/builder.
+package v1alpha4
+
+// DefaultNodeCount is the value `--nodes` is given when the user didn't set it explicitly
+const DefaultNodeCount = 2
+
+// ClusterProvider exposes the AWS clients and metadata the CloudFormation builders need
+type ClusterProvider interface {
+	Region() string
+}
+
+// ClusterMeta holds the identifying information for a cluster
+type ClusterMeta struct {
+	Name   string
+	Region string
+}
+
+// Network describes a single subnet
+type Network struct {
+	ID string
+}
+
+// SubnetTopology is either public or private
+type SubnetTopology string
+
+const (
+	// SubnetTopologyPublic is the topology of a subnet that routes to an internet gateway
+	SubnetTopologyPublic SubnetTopology = "Public"
+	// SubnetTopologyPrivate is the topology of a subnet that routes to a NAT gateway
+	SubnetTopologyPrivate SubnetTopology = "Private"
+)
+
+// ClusterVPC holds the VPC and subnet layout of a cluster
+type ClusterVPC struct {
+	ID      string
+	Subnets map[SubnetTopology]map[string]Network
+}
+
+// ClusterConfig is the top-level description of a cluster and the nodegroups attached to it
+type ClusterConfig struct {
+	Metadata   *ClusterMeta
+	VPC        *ClusterVPC
+	NodeGroups []*NodeGroup
+}
+
+// NodeGroupProvisioner selects how a nodegroup's instances are actually provisioned
+type NodeGroupProvisioner string
+
+const (
+	// NodeGroupProvisionerASG provisions the nodegroup as an Auto Scaling Group (the default)
+	NodeGroupProvisionerASG NodeGroupProvisioner = "asg"
+	// NodeGroupProvisionerFleet provisions the nodegroup as an EC2 Fleet of type "maintain"
+	NodeGroupProvisionerFleet NodeGroupProvisioner = "fleet"
+)
+
+// NodeGroupSecurityGroups controls which security groups a nodegroup's instances run with
+type NodeGroupSecurityGroups struct {
+	// WithShared attaches the cluster's shared control-plane-to-node security group
+	WithShared bool
+	// WithLocal creates (and owns) a security group local to this nodegroup
+	WithLocal bool
+	// AttachIDs are pre-existing security group IDs to attach as-is; when set, eksctl neither
+	// creates nor deletes a local security group for the nodegroup
+	AttachIDs []string
+}
+
+// NodeGroupIAM holds the IAM configuration for a nodegroup's instance role
+type NodeGroupIAM struct {
+	WithAddonPolicies NodeGroupIAMAddonPolicies
+}
+
+// NodeGroupIAMAddonPolicies toggles extra IAM policies attached for built-in addon integrations
+type NodeGroupIAMAddonPolicies struct {
+	// AutoScaler enables the k8s.io/cluster-autoscaler/* ASG tags; it adds no IAM policy to the
+	// node instance role
+	AutoScaler *bool
+}
+
+// NodeGroupVolume describes a single non-root EBS volume attached to a nodegroup's instances
+type NodeGroupVolume struct {
+	Device              string
+	VolumeSize          int
+	VolumeType          string
+	Encrypted           *bool
+	KmsKeyID            *string
+	IOPS                *int
+	Throughput          *int
+	DeleteOnTermination *bool
+	SnapshotID          *string
+
+	// MountPath, when set, has nodebootstrap.NewUserData format this volume and mount it there
+	// (e.g. "/var/lib/docker" or "/var/lib/kubelet"); when unset, the volume is attached but left
+	// unformatted and unmounted.
+	MountPath string
+	// FilesystemType is the filesystem MountPath is formatted with; defaults to "ext4"
+	FilesystemType string
+}
+
+// NodeGroupInstancesDistribution configures the ASG MixedInstancesPolicy used when a nodegroup
+// should draw capacity from several instance types and/or a mix of on-demand and spot
+type NodeGroupInstancesDistribution struct {
+	InstanceTypes                       []string
+	OnDemandBaseCapacity                *int
+	OnDemandPercentageAboveBaseCapacity *int
+	SpotAllocationStrategy              *string
+	SpotInstancePools                   *int
+	SpotMaxPrice                        *string
+}
+
+// NodeGroupFleetConfig configures the EC2 Fleet allocation strategies and on-demand/spot capacity
+// split used when NodeGroup.Provisioner is NodeGroupProvisionerFleet
+type NodeGroupFleetConfig struct {
+	SpotAllocationStrategy     *string
+	OnDemandAllocationStrategy *string
+
+	// OnDemandTargetCapacity and SpotTargetCapacity split the fleet's TotalTargetCapacity between
+	// on-demand and spot instances; capacity assigned by neither is spot when SpotAllocationStrategy
+	// is set and on-demand otherwise
+	OnDemandTargetCapacity *int
+	SpotTargetCapacity     *int
+}
+
+// NodeGroup is the spec for a single nodegroup within a ClusterConfig
+type NodeGroup struct {
+	Name string
+
+	AMI              string
+	InstanceType     string
+	InstanceTypes    []string
+	AllowSSH         bool
+	SSHPublicKeyName string
+
+	MinSize         int
+	MaxSize         int
+	DesiredCapacity int
+
+	PrivateNetworking bool
+
+	// AvailabilityZones is the set of AZs this nodegroup should span; when ASGPerAZ is set, one ASG
+	// is created per AZ instead of a single ASG spanning all of them (ASGPerAZ has no effect on a
+	// nodegroup with an InstancesDistribution, which always gets a single ASG)
+	AvailabilityZones []string
+	// Subnets are explicit subnet IDs (or names resolved against the cluster's VPC config) the
+	// nodegroup should target, taking precedence over AvailabilityZones when set
+	Subnets  []string
+	ASGPerAZ bool
+
+	SecurityGroups NodeGroupSecurityGroups
+	IAM            NodeGroupIAM
+
+	VolumeSize                int
+	VolumeType                string
+	VolumeEncrypted           *bool
+	VolumeKmsKeyID            *string
+	VolumeIOPS                *int
+	VolumeThroughput          *int
+	VolumeDeleteOnTermination *bool
+	VolumeSnapshotID          *string
+	AdditionalVolumes         []NodeGroupVolume
+
+	InstancesDistribution *NodeGroupInstancesDistribution
+
+	Provisioner NodeGroupProvisioner
+	FleetConfig *NodeGroupFleetConfig
+
+	// Labels and Taints are surfaced both as cluster-autoscaler node-template ASG tags (so CA can
+	// size a scaled-to-zero nodegroup) and as kubelet --node-labels/--register-with-taints flags (so
+	// the real nodes match what was advertised)
+	Labels map[string]string
+	Taints map[string]string
+}
+
+// SubnetTopology returns the subnet topology this nodegroup's instances should be placed in
+func (ng *NodeGroup) SubnetTopology() SubnetTopology {
+	if ng.PrivateNetworking {
+		return SubnetTopologyPrivate
+	}
+	return SubnetTopologyPublic
+}
@@ -2,6 +2,8 @@ package builder
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/kris-nova/logger"
 
@@ -13,77 +15,129 @@ import (
 	"github.com/weaveworks/eksctl/pkg/nodebootstrap"
 )
 
-// NodeGroupResourceSet stores the resource information of the node group
+// nodeGroupContext holds the per-nodegroup CloudFormation values (IAM instance profile, security
+// groups, userdata) that used to live directly on NodeGroupResourceSet back when a resource set
+// only ever described a single nodegroup.
+type nodeGroupContext struct {
+	// instanceProfile is an Fn::Ref to the instance profile, which CloudFormation resolves to its
+	// *name*; this is what AWSAutoScalingLaunchConfiguration.IamInstanceProfile expects.
+	instanceProfile *gfn.Value
+	// instanceProfileArn is an Fn::GetAtt of the instance profile's Arn attribute; launch templates'
+	// IamInstanceProfile.Arn field specifically needs the ARN, not the name Ref resolves to.
+	instanceProfileArn *gfn.Value
+	securityGroups     []*gfn.Value
+	userData           *gfn.Value
+}
+
+// NodeGroupResourceSet stores the resource information of one or more node groups that are realized
+// together as a single CloudFormation stack
 type NodeGroupResourceSet struct {
 	rs               *resourceSet
 	clusterSpec      *api.ClusterConfig
-	spec             *api.NodeGroup
+	specs            []*api.NodeGroup
 	provider         api.ClusterProvider
 	clusterStackName string
-	nodeGroupName    string
-	instanceProfile  *gfn.Value
-	securityGroups   []*gfn.Value
 	vpc              *gfn.Value
-	userData         *gfn.Value
+
+	sgCache  map[string][]*gfn.Value
+	iamCache map[string]*gfn.Value
 }
 
-// NewNodeGroupResourceSet returns a resource set for a node group embedded in a cluster config
-func NewNodeGroupResourceSet(provider api.ClusterProvider, spec *api.ClusterConfig, clusterStackName string, ng *api.NodeGroup) *NodeGroupResourceSet {
+// NewNodeGroupResourceSet returns a resource set for one or more node groups embedded in a cluster
+// config. Existing single-nodegroup callers keep working by passing a one-element slice.
+func NewNodeGroupResourceSet(provider api.ClusterProvider, spec *api.ClusterConfig, clusterStackName string, nodeGroups []*api.NodeGroup) *NodeGroupResourceSet {
 	return &NodeGroupResourceSet{
 		rs:               newResourceSet(),
 		clusterStackName: clusterStackName,
-		nodeGroupName:    ng.Name,
 		clusterSpec:      spec,
-		spec:             ng,
+		specs:            nodeGroups,
 		provider:         provider,
+		sgCache:          make(map[string][]*gfn.Value),
+		iamCache:         make(map[string]*gfn.Value),
 	}
 }
 
-// AddAllResources adds all the information about the node group to the resource set
+// AddAllResources adds all the information about every node group in the set to the resource set
 func (n *NodeGroupResourceSet) AddAllResources() error {
+	names := make([]string, len(n.specs))
+	for i, ng := range n.specs {
+		names[i] = ng.Name
+	}
 	n.rs.template.Description = fmt.Sprintf(
-		"%s (AMI family: %s, SSH access: %v, subnet topology: %s) %s",
+		"%s (%d nodegroups: %s) %s",
 		nodeGroupTemplateDescription,
-		n.spec.AMIFamily, n.spec.AllowSSH, n.spec.SubnetTopology(),
+		len(n.specs), strings.Join(names, ", "),
 		templateDescriptionSuffix)
 
-	n.rs.defineOutputWithoutCollector(outputs.NodeGroupFeaturePrivateNetworking, n.spec.PrivateNetworking, false)
-	n.rs.defineOutputWithoutCollector(outputs.NodeGroupFeatureSharedSecurityGroup, n.spec.SecurityGroups.WithShared, false)
-	n.rs.defineOutputWithoutCollector(outputs.NodeGroupFeatureLocalSecurityGroup, n.spec.SecurityGroups.WithLocal, false)
+	if err := checkForDuplicateSuffixes(n.specs); err != nil {
+		return err
+	}
 
 	n.vpc = makeImportValue(n.clusterStackName, outputs.ClusterVPC)
 
-	userData, err := nodebootstrap.NewUserData(n.clusterSpec, n.spec)
+	for _, ng := range n.specs {
+		if err := n.addResourcesForNodeGroupSpec(ng); err != nil {
+			return fmt.Errorf("nodegroup %q: %w", ng.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// addResourcesForNodeGroupSpec adds every resource that belongs to a single nodegroup within the
+// (possibly multi-nodegroup) stack. IAM roles and security groups are shared across nodegroups
+// whose relevant spec fields match, instead of being recreated per nodegroup.
+func (n *NodeGroupResourceSet) addResourcesForNodeGroupSpec(ng *api.NodeGroup) error {
+	suffix := resourceSuffix(ng.Name)
+
+	n.rs.defineOutputWithoutCollector(outputs.NodeGroupFeaturePrivateNetworking+suffix, ng.PrivateNetworking, false)
+	n.rs.defineOutputWithoutCollector(outputs.NodeGroupFeatureSharedSecurityGroup+suffix, ng.SecurityGroups.WithShared, false)
+	n.rs.defineOutputWithoutCollector(outputs.NodeGroupFeatureLocalSecurityGroup+suffix, ng.SecurityGroups.WithLocal, false)
+
+	userData, err := nodebootstrap.NewUserData(n.clusterSpec, ng)
 	if err != nil {
 		return err
 	}
-	n.userData = gfn.NewString(userData)
 
 	switch {
-	case n.spec.MinSize == 0 && n.spec.MaxSize == 0:
-		n.spec.MinSize = n.spec.DesiredCapacity
-		n.spec.MaxSize = n.spec.DesiredCapacity
-	case n.spec.MinSize > 0 && n.spec.MaxSize > 0:
-		if n.spec.DesiredCapacity == api.DefaultNodeCount {
-			msgPrefix := fmt.Sprintf("as --nodes-min=%d and --nodes-max=%d were given", n.spec.MinSize, n.spec.MaxSize)
-			if n.spec.DesiredCapacity < n.spec.MinSize {
-				n.spec.DesiredCapacity = n.spec.MaxSize
+	case ng.MinSize == 0 && ng.MaxSize == 0:
+		ng.MinSize = ng.DesiredCapacity
+		ng.MaxSize = ng.DesiredCapacity
+	case ng.MinSize > 0 && ng.MaxSize > 0:
+		if ng.DesiredCapacity == api.DefaultNodeCount {
+			msgPrefix := fmt.Sprintf("as --nodes-min=%d and --nodes-max=%d were given", ng.MinSize, ng.MaxSize)
+			if ng.DesiredCapacity < ng.MinSize {
+				ng.DesiredCapacity = ng.MaxSize
 				logger.Info("%s, --nodes=%d was set automatically as default value (--node=%d) was outside the set renge",
-					msgPrefix, n.spec.DesiredCapacity, api.DefaultNodeCount)
+					msgPrefix, ng.DesiredCapacity, api.DefaultNodeCount)
 			} else {
 				logger.Info("%s, default value of --nodes=%d was kept as it is within the set range",
-					msgPrefix, n.spec.DesiredCapacity)
+					msgPrefix, ng.DesiredCapacity)
 			}
 		}
-		if n.spec.DesiredCapacity > n.spec.MaxSize {
-			return fmt.Errorf("cannot use --nodes-max=%d and --nodes=%d at the same time", n.spec.MaxSize, n.spec.DesiredCapacity)
+		if ng.DesiredCapacity > ng.MaxSize {
+			return fmt.Errorf("cannot use --nodes-max=%d and --nodes=%d at the same time", ng.MaxSize, ng.DesiredCapacity)
 		}
 	}
 
-	n.addResourcesForIAM()
-	n.addResourcesForSecurityGroups()
+	// a nodegroup whose IAM spec matches an earlier one reuses that nodegroup's instance profile
+	// (see addResourcesForIAM), so the ARN is read off the profile named after the earlier one
+	profileSuffix := suffix
+	for _, other := range n.specs {
+		if iamCacheKey(other.IAM) == iamCacheKey(ng.IAM) {
+			profileSuffix = resourceSuffix(other.Name)
+			break
+		}
+	}
+
+	ctx := &nodeGroupContext{
+		userData:           gfn.NewString(userData),
+		instanceProfile:    n.addResourcesForIAM(ng, suffix),
+		instanceProfileArn: gfn.MakeFnGetAttString("NodeInstanceProfile" + profileSuffix + ".Arn"),
+		securityGroups:     n.addResourcesForSecurityGroups(ng, suffix),
+	}
 
-	return n.addResourcesForNodeGroup()
+	return n.addResourcesForNodeGroup(ng, suffix, ctx)
 }
 
 // RenderJSON returns the rendered JSON
@@ -100,63 +154,777 @@ func (n *NodeGroupResourceSet) newResource(name string, resource interface{}) *g
 	return n.rs.newResource(name, resource)
 }
 
-func (n *NodeGroupResourceSet) addResourcesForNodeGroup() error {
+// nodeInstanceRoleManagedPolicyARNs are the AWS managed policies every EKS worker node's instance
+// role needs regardless of nodegroup spec: joining the cluster, CNI networking, and pulling images.
+var nodeInstanceRoleManagedPolicyARNs = []*gfn.Value{
+	gfn.NewString("arn:aws:iam::aws:policy/AmazonEKSWorkerNodePolicy"),
+	gfn.NewString("arn:aws:iam::aws:policy/AmazonEKS_CNI_Policy"),
+	gfn.NewString("arn:aws:iam::aws:policy/AmazonEC2ContainerRegistryReadOnly"),
+}
+
+// addResourcesForIAM creates the instance role and instance profile the nodegroup's instances
+// should launch with, and returns a reference to the profile.
+//
+// Nodegroups whose IAM spec is identical share the same instance role/profile instead of each
+// getting their own, mirroring the security-group sharing done by addResourcesForSecurityGroups.
+func (n *NodeGroupResourceSet) addResourcesForIAM(ng *api.NodeGroup, suffix string) *gfn.Value {
+	cacheKey := iamCacheKey(ng.IAM)
+	if cached, ok := n.iamCache[cacheKey]; ok {
+		return cached
+	}
+
+	n.newResource("NodeInstanceRole"+suffix, &gfn.AWSIAMRole{
+		Path: gfn.NewString("/"),
+		AssumeRolePolicyDocument: map[string]interface{}{
+			"Version": "2012-10-17",
+			"Statement": []map[string]interface{}{
+				{
+					"Effect":    "Allow",
+					"Principal": map[string]interface{}{"Service": []string{"ec2.amazonaws.com"}},
+					"Action":    []string{"sts:AssumeRole"},
+				},
+			},
+		},
+		ManagedPolicyArns: nodeInstanceRoleManagedPolicyARNs,
+	})
+
+	profile := n.newResource("NodeInstanceProfile"+suffix, &gfn.AWSIAMInstanceProfile{
+		Path:  gfn.NewString("/"),
+		Roles: []*gfn.Value{gfn.MakeRef("NodeInstanceRole" + suffix)},
+	})
+	n.iamCache[cacheKey] = profile
+	return profile
+}
+
+// iamCacheKey builds a dedup key for a nodegroup's IAM spec. fmt.Sprintf("%v", ...) can't be used
+// directly here: AutoScaler is a *bool, and %v only dereferences a pointer when it's the top-level
+// argument, not when it's nested inside a struct, so two equivalent specs with distinct *bool
+// allocations would otherwise get different keys and never dedup.
+func iamCacheKey(iam api.NodeGroupIAM) string {
+	autoScaler := "nil"
+	if v := iam.WithAddonPolicies.AutoScaler; v != nil {
+		autoScaler = fmt.Sprintf("%v", *v)
+	}
+	return "autoScaler=" + autoScaler
+}
+
+// addResourcesForSecurityGroups attaches the security groups the nodegroup's instances should run
+// with. If the user has supplied pre-existing SG IDs via `SecurityGroups.AttachIDs`, those are wired
+// in as-is and eksctl neither creates nor deletes them; otherwise a local SG owned by this stack is
+// created when `SecurityGroups.WithLocal` is set. The cluster's shared SG is merged in either way
+// when `SecurityGroups.WithShared` is set.
+//
+// Nodegroups whose SecurityGroups spec is identical share the same local SG instead of each
+// getting their own.
+//
+// Which SGs are attach-only vs owned by this stack is recorded as outputs so that
+// `eksctl delete nodegroup` can tell them apart and never attempts to delete an SG it didn't create.
+func (n *NodeGroupResourceSet) addResourcesForSecurityGroups(ng *api.NodeGroup, suffix string) []*gfn.Value {
+	if len(ng.SecurityGroups.AttachIDs) > 0 {
+		n.rs.defineOutputWithoutCollector(outputs.NodeGroupSecurityGroupAttachIDs+suffix, strings.Join(ng.SecurityGroups.AttachIDs, ","), false)
+	}
+
+	cacheKey := fmt.Sprintf("%v", ng.SecurityGroups)
+	if cached, ok := n.sgCache[cacheKey]; ok {
+		if len(ng.SecurityGroups.AttachIDs) == 0 && ng.SecurityGroups.WithLocal {
+			n.rs.defineOutputWithoutCollector(outputs.NodeGroupSecurityGroupLocalID+suffix, cached[0], false)
+		}
+		return cached
+	}
+
+	var sgs []*gfn.Value
+
+	if len(ng.SecurityGroups.AttachIDs) > 0 {
+		for _, id := range ng.SecurityGroups.AttachIDs {
+			sgs = append(sgs, gfn.NewString(id))
+		}
+	} else if ng.SecurityGroups.WithLocal {
+		refSG := n.newResource("SG"+suffix, &gfn.AWSEC2SecurityGroup{
+			VpcId:            n.vpc,
+			GroupDescription: gfn.NewString(fmt.Sprintf("Communication between the control plane and worker nodegroup %s", ng.Name)),
+			Tags: []gfn.Tag{
+				{
+					Key:   gfn.NewString("kubernetes.io/cluster/" + n.clusterSpec.Metadata.Name),
+					Value: gfn.NewString("owned"),
+				},
+			},
+		})
+		sgs = append(sgs, refSG)
+		n.rs.defineOutputWithoutCollector(outputs.NodeGroupSecurityGroupLocalID+suffix, refSG, false)
+	}
+
+	if ng.SecurityGroups.WithShared {
+		sgs = append(sgs, makeImportValue(n.clusterStackName, outputs.ClusterSecurityGroup))
+	}
+
+	n.sgCache[cacheKey] = sgs
+	return sgs
+}
+
+func (n *NodeGroupResourceSet) addResourcesForNodeGroup(ng *api.NodeGroup, suffix string, ctx *nodeGroupContext) error {
+	switch ng.Provisioner {
+	case "", api.NodeGroupProvisionerASG:
+		// fall through to the ASG-based provisioning below
+	case api.NodeGroupProvisionerFleet:
+		return n.addResourcesForNodeGroupWithFleet(ng, suffix, ctx)
+	default:
+		return fmt.Errorf("unknown nodegroup provisioner %q", ng.Provisioner)
+	}
+
+	if ng.InstancesDistribution != nil {
+		return n.addResourcesForNodeGroupWithMixedInstancesPolicy(ng, suffix, ctx)
+	}
+	if ng.ASGPerAZ && (len(ng.Subnets) > 1 || len(ng.AvailabilityZones) > 1) {
+		return n.addResourcesForNodeGroupPerAZ(ng, suffix, ctx)
+	}
+
 	lc := &gfn.AWSAutoScalingLaunchConfiguration{
-		IamInstanceProfile: n.instanceProfile,
-		SecurityGroups:     n.securityGroups,
-		ImageId:            gfn.NewString(n.spec.AMI),
-		InstanceType:       gfn.NewString(n.spec.InstanceType),
-		UserData:           n.userData,
+		IamInstanceProfile: ctx.instanceProfile,
+		SecurityGroups:     ctx.securityGroups,
+		ImageId:            gfn.NewString(ng.AMI),
+		InstanceType:       gfn.NewString(ng.InstanceType),
+		UserData:           ctx.userData,
 	}
-	if n.spec.AllowSSH {
-		lc.KeyName = gfn.NewString(n.spec.SSHPublicKeyName)
+	if ng.AllowSSH {
+		lc.KeyName = gfn.NewString(ng.SSHPublicKeyName)
 	}
-	if n.spec.PrivateNetworking {
+	if ng.PrivateNetworking {
 		lc.AssociatePublicIpAddress = gfn.False()
 	} else {
 		lc.AssociatePublicIpAddress = gfn.True()
 	}
-	if n.spec.VolumeSize > 0 {
-		lc.BlockDeviceMappings = []gfn.AWSAutoScalingLaunchConfiguration_BlockDeviceMapping{
+	blockDeviceMappings, err := makeLaunchConfigBlockDeviceMappings(ng)
+	if err != nil {
+		return err
+	}
+	lc.BlockDeviceMappings = blockDeviceMappings
+
+	refLC := n.newResource("NodeLaunchConfig"+suffix, lc)
+
+	vpcZoneIdentifier, err := n.makeVPCZoneIdentifier(ng)
+	if err != nil {
+		return err
+	}
+
+	n.newResource("NodeGroup"+suffix, &awsCloudFormationResource{
+		Type: "AWS::AutoScaling::AutoScalingGroup",
+		Properties: map[string]interface{}{
+			"LaunchConfigurationName": refLC,
+			"DesiredCapacity":         fmt.Sprintf("%d", ng.DesiredCapacity),
+			"MinSize":                 fmt.Sprintf("%d", ng.MinSize),
+			"MaxSize":                 fmt.Sprintf("%d", ng.MaxSize),
+			"VPCZoneIdentifier":       vpcZoneIdentifier,
+			"Tags":                    n.makeNodeGroupTags(ng),
+		},
+		UpdatePolicy: map[string]map[string]string{
+			"AutoScalingRollingUpdate": {
+				"MinInstancesInService": "1",
+				"MaxBatchSize":          "1",
+			},
+		},
+	})
+
+	return nil
+}
+
+// addResourcesForNodeGroupWithMixedInstancesPolicy renders an ASG that is backed by a launch
+// template and a MixedInstancesPolicy, so that the nodegroup can draw capacity from several
+// instance types and from a combination of on-demand and spot instances
+func (n *NodeGroupResourceSet) addResourcesForNodeGroupWithMixedInstancesPolicy(ng *api.NodeGroup, suffix string, ctx *nodeGroupContext) error {
+	dist := ng.InstancesDistribution
+	if len(dist.InstanceTypes) == 0 {
+		return fmt.Errorf("instancesDistribution.instanceTypes must contain at least one instance type")
+	}
+
+	ltData, err := n.makeLaunchTemplateData(ng, ctx, dist.InstanceTypes[0])
+	if err != nil {
+		return err
+	}
+
+	refLT := n.newResource("NodeLaunchTemplate"+suffix, &gfn.AWSEC2LaunchTemplate{
+		LaunchTemplateName: gfn.NewString(fmt.Sprintf("%s-%s", n.clusterSpec.Metadata.Name, ng.Name)),
+		LaunchTemplateData: ltData,
+	})
+
+	overrides := make([]map[string]interface{}, len(dist.InstanceTypes))
+	for i, instanceType := range dist.InstanceTypes {
+		overrides[i] = map[string]interface{}{
+			"InstanceType": instanceType,
+		}
+	}
+
+	instancesDistribution := map[string]interface{}{}
+	if dist.OnDemandBaseCapacity != nil {
+		instancesDistribution["OnDemandBaseCapacity"] = *dist.OnDemandBaseCapacity
+	}
+	if dist.OnDemandPercentageAboveBaseCapacity != nil {
+		instancesDistribution["OnDemandPercentageAboveBaseCapacity"] = *dist.OnDemandPercentageAboveBaseCapacity
+	}
+	if dist.SpotAllocationStrategy != nil {
+		instancesDistribution["SpotAllocationStrategy"] = *dist.SpotAllocationStrategy
+	}
+	if dist.SpotInstancePools != nil {
+		instancesDistribution["SpotInstancePools"] = *dist.SpotInstancePools
+	}
+	if dist.SpotMaxPrice != nil {
+		instancesDistribution["SpotMaxPrice"] = *dist.SpotMaxPrice
+	}
+
+	vpcZoneIdentifier, err := n.makeVPCZoneIdentifier(ng)
+	if err != nil {
+		return err
+	}
+
+	n.newResource("NodeGroup"+suffix, &awsCloudFormationResource{
+		Type: "AWS::AutoScaling::AutoScalingGroup",
+		Properties: map[string]interface{}{
+			"MixedInstancesPolicy": map[string]interface{}{
+				"LaunchTemplate": map[string]interface{}{
+					"LaunchTemplateSpecification": map[string]interface{}{
+						"LaunchTemplateId": refLT,
+						"Version":          gfn.MakeFnGetAttString("NodeLaunchTemplate" + suffix + ".LatestVersionNumber"),
+					},
+					"Overrides": overrides,
+				},
+				"InstancesDistribution": instancesDistribution,
+			},
+			"DesiredCapacity":   fmt.Sprintf("%d", ng.DesiredCapacity),
+			"MinSize":           fmt.Sprintf("%d", ng.MinSize),
+			"MaxSize":           fmt.Sprintf("%d", ng.MaxSize),
+			"VPCZoneIdentifier": vpcZoneIdentifier,
+			"Tags":              n.makeNodeGroupTags(ng),
+		},
+		UpdatePolicy: map[string]map[string]string{
+			"AutoScalingRollingUpdate": {
+				"MinInstancesInService": "1",
+				"MaxBatchSize":          "1",
+			},
+		},
+	})
+
+	return nil
+}
+
+// makeLaunchTemplateData builds the `AWS::EC2::LaunchTemplate` LaunchTemplateData shared by the
+// mixed-instances-policy and EC2 Fleet provisioning paths, both of which launch via a template
+// rather than a plain `AWS::AutoScaling::LaunchConfiguration`.
+func (n *NodeGroupResourceSet) makeLaunchTemplateData(ng *api.NodeGroup, ctx *nodeGroupContext, instanceType string) (*gfn.AWSEC2LaunchTemplate_LaunchTemplateData, error) {
+	ltData := &gfn.AWSEC2LaunchTemplate_LaunchTemplateData{
+		IamInstanceProfile: &gfn.AWSEC2LaunchTemplate_IamInstanceProfile{
+			// Ref on AWS::IAM::InstanceProfile resolves to the profile's *name*, not its ARN, but
+			// this field specifically requires the ARN.
+			Arn: ctx.instanceProfileArn,
+		},
+		ImageId:      gfn.NewString(ng.AMI),
+		InstanceType: gfn.NewString(instanceType),
+		UserData:     ctx.userData,
+	}
+	if ng.AllowSSH {
+		ltData.KeyName = gfn.NewString(ng.SSHPublicKeyName)
+	}
+	// The security groups always go on the NetworkInterface, never on the top-level
+	// SecurityGroupIds too: AWS rejects a launch template/RunInstances request that sets both an
+	// instance-level security group parameter and a NetworkInterfaces-scoped one. Routing them
+	// through the NetworkInterface also lets us set AssociatePublicIpAddress explicitly either way,
+	// matching the plain-ASG launch-configuration path instead of leaving it to the AMI/subnet
+	// default when private networking is off.
+	ltData.NetworkInterfaces = []gfn.AWSEC2LaunchTemplate_NetworkInterface{
+		{
+			AssociatePublicIpAddress: gfn.NewBoolean(!ng.PrivateNetworking),
+			DeviceIndex:              gfn.NewInteger(0),
+			Groups:                   ctx.securityGroups,
+		},
+	}
+
+	blockDeviceMappings, err := makeLaunchTemplateBlockDeviceMappings(ng)
+	if err != nil {
+		return nil, err
+	}
+	ltData.BlockDeviceMappings = blockDeviceMappings
+
+	ltData.TagSpecifications = []gfn.AWSEC2LaunchTemplate_TagSpecification{
+		{
+			ResourceType: gfn.NewString("instance"),
+			Tags:         n.makeNodeGroupTagsWithoutPropagateAtLaunch(ng),
+		},
+	}
+
+	return ltData, nil
+}
+
+// addResourcesForNodeGroupWithFleet realizes the nodegroup as an `AWS::EC2::EC2Fleet` of type
+// "maintain" instead of an ASG. This gives access to instance-type overrides and spot/on-demand
+// allocation strategies that are expressed less directly through an ASG's MixedInstancesPolicy,
+// and is better suited to large, diverse, spot-heavy batch nodegroups. Cluster-autoscaler
+// integration keeps working the same way, since the fleet's instances carry the same
+// `k8s.io/cluster-autoscaler/*` tags as an ASG-backed nodegroup. Unlike an ASG, a fleet has no
+// VPCZoneIdentifier, so every LaunchTemplateConfig override carries an explicit SubnetId, resolved
+// the same way as the ASG path's subnet targeting.
+func (n *NodeGroupResourceSet) addResourcesForNodeGroupWithFleet(ng *api.NodeGroup, suffix string, ctx *nodeGroupContext) error {
+	instanceTypes := ng.InstanceTypes
+	if len(instanceTypes) == 0 {
+		instanceTypes = []string{ng.InstanceType}
+	}
+
+	ltData, err := n.makeLaunchTemplateData(ng, ctx, instanceTypes[0])
+	if err != nil {
+		return err
+	}
+
+	refLT := n.newResource("NodeLaunchTemplate"+suffix, &gfn.AWSEC2LaunchTemplate{
+		LaunchTemplateName: gfn.NewString(fmt.Sprintf("%s-%s", n.clusterSpec.Metadata.Name, ng.Name)),
+		LaunchTemplateData: ltData,
+	})
+
+	subnetIDs, err := n.resolveFleetSubnetIDs(ng)
+	if err != nil {
+		return err
+	}
+
+	overrides := make([]map[string]interface{}, 0, len(instanceTypes)*len(subnetIDs))
+	for _, instanceType := range instanceTypes {
+		for _, subnetID := range subnetIDs {
+			overrides = append(overrides, map[string]interface{}{
+				"InstanceType": instanceType,
+				"SubnetId":     subnetID,
+			})
+		}
+	}
+
+	targetCapacitySpecification := map[string]interface{}{
+		"TotalTargetCapacity":       ng.MaxSize,
+		"DefaultTargetCapacityType": "on-demand",
+	}
+	if ng.DesiredCapacity > 0 {
+		targetCapacitySpecification["TotalTargetCapacity"] = ng.DesiredCapacity
+	}
+	if fc := ng.FleetConfig; fc != nil {
+		// a fleet that was given a spot allocation strategy defaults to spot capacity; otherwise any
+		// capacity not assigned by OnDemandTargetCapacity/SpotTargetCapacity is on-demand.
+		if fc.SpotAllocationStrategy != nil {
+			targetCapacitySpecification["DefaultTargetCapacityType"] = "spot"
+		}
+		if fc.OnDemandTargetCapacity != nil {
+			targetCapacitySpecification["OnDemandTargetCapacity"] = *fc.OnDemandTargetCapacity
+		}
+		if fc.SpotTargetCapacity != nil {
+			targetCapacitySpecification["SpotTargetCapacity"] = *fc.SpotTargetCapacity
+		}
+	}
+
+	fleetProperties := map[string]interface{}{
+		"Type": "maintain",
+		"LaunchTemplateConfigs": []map[string]interface{}{
 			{
-				DeviceName: gfn.NewString("/dev/xvda"),
-				Ebs: &gfn.AWSAutoScalingLaunchConfiguration_BlockDevice{
-					VolumeSize: gfn.NewInteger(n.spec.VolumeSize),
-					VolumeType: gfn.NewString(n.spec.VolumeType),
+				"LaunchTemplateSpecification": map[string]interface{}{
+					"LaunchTemplateId": refLT,
+					"Version":          gfn.MakeFnGetAttString("NodeLaunchTemplate" + suffix + ".LatestVersionNumber"),
 				},
+				"Overrides": overrides,
+			},
+		},
+		"TargetCapacitySpecification": targetCapacitySpecification,
+		"TagSpecifications": []map[string]interface{}{
+			{
+				"ResourceType": "instance",
+				"Tags":         n.makeNodeGroupTagsWithoutPropagateAtLaunch(ng),
 			},
+		},
+	}
+	if fc := ng.FleetConfig; fc != nil {
+		if fc.SpotAllocationStrategy != nil {
+			fleetProperties["SpotOptions"] = map[string]interface{}{
+				"AllocationStrategy": *fc.SpotAllocationStrategy,
+			}
+		}
+		if fc.OnDemandAllocationStrategy != nil {
+			fleetProperties["OnDemandOptions"] = map[string]interface{}{
+				"AllocationStrategy": *fc.OnDemandAllocationStrategy,
+			}
 		}
 	}
-	refLC := n.newResource("NodeLaunchConfig", lc)
-	// currently goformation type system doesn't allow specifying `VPCZoneIdentifier: { "Fn::ImportValue": ... }`,
-	// and tags don't have `PropagateAtLaunch` field, so we have a custom method here until this gets resolved
-	var vpcZoneIdentifier interface{}
-	if numNodeGroupsAZs := len(n.spec.AvailabilityZones); numNodeGroupsAZs > 0 {
-		subnets := n.clusterSpec.VPC.Subnets[n.spec.SubnetTopology()]
-		errorDesc := fmt.Sprintf("(subnets=%#v AZs=%#v)", subnets, n.spec.AvailabilityZones)
+
+	n.newResource("NodeFleet"+suffix, &awsCloudFormationResource{
+		Type:       "AWS::EC2::EC2Fleet",
+		Properties: fleetProperties,
+	})
+
+	return nil
+}
+
+// makeLaunchConfigBlockDeviceMappings builds the root volume mapping (with its encryption, IOPS and
+// throughput settings) plus any additional, non-root volumes requested for the nodegroup, in the
+// shape expected by an `AWS::AutoScaling::LaunchConfiguration`.
+func makeLaunchConfigBlockDeviceMappings(ng *api.NodeGroup) ([]gfn.AWSAutoScalingLaunchConfiguration_BlockDeviceMapping, error) {
+	var mappings []gfn.AWSAutoScalingLaunchConfiguration_BlockDeviceMapping
+
+	if ng.VolumeSize > 0 {
+		if err := validateVolumeOptions(ng.VolumeType, ng.VolumeIOPS, ng.VolumeThroughput); err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, gfn.AWSAutoScalingLaunchConfiguration_BlockDeviceMapping{
+			DeviceName: gfn.NewString("/dev/xvda"),
+			Ebs: &gfn.AWSAutoScalingLaunchConfiguration_BlockDevice{
+				VolumeSize:          gfn.NewInteger(ng.VolumeSize),
+				VolumeType:          gfn.NewString(ng.VolumeType),
+				Encrypted:           optionalBoolean(ng.VolumeEncrypted),
+				KmsKeyId:            optionalString(ng.VolumeKmsKeyID),
+				Iops:                optionalInteger(ng.VolumeIOPS),
+				Throughput:          optionalInteger(ng.VolumeThroughput),
+				DeleteOnTermination: optionalBoolean(ng.VolumeDeleteOnTermination),
+				SnapshotId:          optionalString(ng.VolumeSnapshotID),
+			},
+		})
+	}
+
+	for _, vol := range ng.AdditionalVolumes {
+		if err := validateVolumeOptions(vol.VolumeType, vol.IOPS, vol.Throughput); err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, gfn.AWSAutoScalingLaunchConfiguration_BlockDeviceMapping{
+			DeviceName: gfn.NewString(vol.Device),
+			Ebs: &gfn.AWSAutoScalingLaunchConfiguration_BlockDevice{
+				VolumeSize:          gfn.NewInteger(vol.VolumeSize),
+				VolumeType:          gfn.NewString(vol.VolumeType),
+				Encrypted:           optionalBoolean(vol.Encrypted),
+				KmsKeyId:            optionalString(vol.KmsKeyID),
+				Iops:                optionalInteger(vol.IOPS),
+				Throughput:          optionalInteger(vol.Throughput),
+				DeleteOnTermination: optionalBoolean(vol.DeleteOnTermination),
+				SnapshotId:          optionalString(vol.SnapshotID),
+			},
+		})
+	}
+
+	return mappings, nil
+}
+
+// makeLaunchTemplateBlockDeviceMappings is the `AWS::EC2::LaunchTemplate` equivalent of
+// makeLaunchConfigBlockDeviceMappings, used by the mixed-instances-policy and fleet code paths.
+func makeLaunchTemplateBlockDeviceMappings(ng *api.NodeGroup) ([]gfn.AWSEC2LaunchTemplate_BlockDeviceMapping, error) {
+	var mappings []gfn.AWSEC2LaunchTemplate_BlockDeviceMapping
+
+	if ng.VolumeSize > 0 {
+		if err := validateVolumeOptions(ng.VolumeType, ng.VolumeIOPS, ng.VolumeThroughput); err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, gfn.AWSEC2LaunchTemplate_BlockDeviceMapping{
+			DeviceName: gfn.NewString("/dev/xvda"),
+			Ebs: &gfn.AWSEC2LaunchTemplate_Ebs{
+				VolumeSize:          gfn.NewInteger(ng.VolumeSize),
+				VolumeType:          gfn.NewString(ng.VolumeType),
+				Encrypted:           optionalBoolean(ng.VolumeEncrypted),
+				KmsKeyId:            optionalString(ng.VolumeKmsKeyID),
+				Iops:                optionalInteger(ng.VolumeIOPS),
+				Throughput:          optionalInteger(ng.VolumeThroughput),
+				DeleteOnTermination: optionalBoolean(ng.VolumeDeleteOnTermination),
+				SnapshotId:          optionalString(ng.VolumeSnapshotID),
+			},
+		})
+	}
+
+	for _, vol := range ng.AdditionalVolumes {
+		if err := validateVolumeOptions(vol.VolumeType, vol.IOPS, vol.Throughput); err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, gfn.AWSEC2LaunchTemplate_BlockDeviceMapping{
+			DeviceName: gfn.NewString(vol.Device),
+			Ebs: &gfn.AWSEC2LaunchTemplate_Ebs{
+				VolumeSize:          gfn.NewInteger(vol.VolumeSize),
+				VolumeType:          gfn.NewString(vol.VolumeType),
+				Encrypted:           optionalBoolean(vol.Encrypted),
+				KmsKeyId:            optionalString(vol.KmsKeyID),
+				Iops:                optionalInteger(vol.IOPS),
+				Throughput:          optionalInteger(vol.Throughput),
+				DeleteOnTermination: optionalBoolean(vol.DeleteOnTermination),
+				SnapshotId:          optionalString(vol.SnapshotID),
+			},
+		})
+	}
+
+	return mappings, nil
+}
+
+// validateVolumeOptions rejects IOPS/throughput combinations that AWS itself would reject:
+// IOPS may only be set for io1, io2 and gp3 volumes, and throughput only for gp3.
+func validateVolumeOptions(volumeType string, iops, throughput *int) error {
+	if iops != nil {
+		switch volumeType {
+		case "io1", "io2", "gp3":
+		default:
+			return fmt.Errorf("iops is only supported for io1, io2 and gp3 volumes, not %q", volumeType)
+		}
+	}
+	if throughput != nil && volumeType != "gp3" {
+		return fmt.Errorf("throughput is only supported for gp3 volumes, not %q", volumeType)
+	}
+	return nil
+}
+
+func optionalBoolean(v *bool) *gfn.Value {
+	if v == nil {
+		return nil
+	}
+	return gfn.NewBoolean(*v)
+}
+
+func optionalString(v *string) *gfn.Value {
+	if v == nil || *v == "" {
+		return nil
+	}
+	return gfn.NewString(*v)
+}
+
+func optionalInteger(v *int) *gfn.Value {
+	if v == nil {
+		return nil
+	}
+	return gfn.NewInteger(*v)
+}
+
+// makeVPCZoneIdentifier returns the subnets the nodegroup's ASG should span: an explicit list of
+// subnet IDs/names, a caller-supplied set of AZs, or (failing both) the full set of subnets
+// imported from the cluster stack for the nodegroup's topology.
+//
+// currently goformation type system doesn't allow specifying `VPCZoneIdentifier: { "Fn::ImportValue": ... }`,
+// and tags don't have `PropagateAtLaunch` field, so we have a custom method here until this gets resolved
+func (n *NodeGroupResourceSet) makeVPCZoneIdentifier(ng *api.NodeGroup) (interface{}, error) {
+	if len(ng.Subnets) > 0 {
+		ids, err := n.resolveSubnetIDs(ng)
+		if err != nil {
+			return nil, err
+		}
+		vpcZoneIdentifier := make([]interface{}, len(ids))
+		for i, id := range ids {
+			vpcZoneIdentifier[i] = id
+		}
+		return vpcZoneIdentifier, nil
+	}
+	if numNodeGroupsAZs := len(ng.AvailabilityZones); numNodeGroupsAZs > 0 {
+		subnets := n.clusterSpec.VPC.Subnets[ng.SubnetTopology()]
+		errorDesc := fmt.Sprintf("(subnets=%#v AZs=%#v)", subnets, ng.AvailabilityZones)
 		if len(subnets) < numNodeGroupsAZs {
-			return fmt.Errorf("VPC doesn't have enough subnets for nodegroup AZs %s", errorDesc)
+			return nil, fmt.Errorf("VPC doesn't have enough subnets for nodegroup AZs %s", errorDesc)
 		}
-		vpcZoneIdentifier = make([]interface{}, numNodeGroupsAZs)
-		for i, az := range n.spec.AvailabilityZones {
+		vpcZoneIdentifier := make([]interface{}, numNodeGroupsAZs)
+		for i, az := range ng.AvailabilityZones {
 			subnet, ok := subnets[az]
 			if !ok {
-				return fmt.Errorf("VPC doesn't have subnets in %s %s", az, errorDesc)
+				return nil, fmt.Errorf("VPC doesn't have subnets in %s %s", az, errorDesc)
 			}
-			vpcZoneIdentifier.([]interface{})[i] = subnet.ID
+			vpcZoneIdentifier[i] = subnet.ID
 		}
-	} else {
-		vpcZoneIdentifier = map[string][]interface{}{
-			gfn.FnSplit: []interface{}{
-				",",
-				makeImportValue(n.clusterStackName, outputs.ClusterSubnets+string(n.spec.SubnetTopology())),
+		return vpcZoneIdentifier, nil
+	}
+	return map[string][]interface{}{
+		gfn.FnSplit: []interface{}{
+			",",
+			makeImportValue(n.clusterStackName, outputs.ClusterSubnets+string(ng.SubnetTopology())),
+		},
+	}, nil
+}
+
+// resolveSubnetIDs resolves ng.Subnets (a mix of literal subnet IDs and subnet names known to the
+// cluster's VPC config) against the cluster's subnets for the nodegroup's topology, returning one
+// resolved ID per entry in the same order.
+func (n *NodeGroupResourceSet) resolveSubnetIDs(ng *api.NodeGroup) ([]string, error) {
+	subnets := n.clusterSpec.VPC.Subnets[ng.SubnetTopology()]
+	ids := make([]string, len(ng.Subnets))
+	for i, s := range ng.Subnets {
+		if strings.HasPrefix(s, "subnet-") {
+			ids[i] = s
+			continue
+		}
+		subnet, ok := subnets[s]
+		if !ok {
+			return nil, fmt.Errorf("VPC doesn't have a subnet named %q (subnets=%#v)", s, subnets)
+		}
+		ids[i] = subnet.ID
+	}
+	return ids, nil
+}
+
+// resolveFleetSubnetIDs returns the subnet IDs an EC2 Fleet's LaunchTemplateConfig Overrides should
+// place instances in: the nodegroup's explicit Subnets or AvailabilityZones when given, otherwise
+// every subnet of the nodegroup's topology, so that CreateFleet is never left unconstrained to a
+// VPC (and, in accounts with no default VPC, failing outright).
+func (n *NodeGroupResourceSet) resolveFleetSubnetIDs(ng *api.NodeGroup) ([]string, error) {
+	if len(ng.Subnets) > 0 {
+		return n.resolveSubnetIDs(ng)
+	}
+
+	subnets := n.clusterSpec.VPC.Subnets[ng.SubnetTopology()]
+
+	if len(ng.AvailabilityZones) > 0 {
+		ids := make([]string, len(ng.AvailabilityZones))
+		for i, az := range ng.AvailabilityZones {
+			subnet, ok := subnets[az]
+			if !ok {
+				return nil, fmt.Errorf("VPC doesn't have subnets in %s (subnets=%#v AZs=%#v)", az, subnets, ng.AvailabilityZones)
+			}
+			ids[i] = subnet.ID
+		}
+		return ids, nil
+	}
+
+	if len(subnets) == 0 {
+		return nil, fmt.Errorf("nodegroup %q: no subnets available in the VPC to place EC2 Fleet instances in", ng.Name)
+	}
+	ids := make([]string, 0, len(subnets))
+	for _, subnet := range subnets {
+		ids = append(ids, subnet.ID)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// perAZTarget is one child ASG's worth of per-AZ fan-out: the subnet it should launch into and,
+// where known, the AZ that subnet lives in (used for the topology.kubernetes.io/zone CA tag).
+type perAZTarget struct {
+	suffix   string
+	subnetID string
+	az       string
+}
+
+// resolvePerAZTargets returns one target per explicit subnet in ng.Subnets, or (when none are
+// given) one target per ng.AvailabilityZones, resolved against the cluster's subnets for the
+// nodegroup's topology.
+func (n *NodeGroupResourceSet) resolvePerAZTargets(ng *api.NodeGroup) ([]perAZTarget, error) {
+	if len(ng.Subnets) > 0 {
+		ids, err := n.resolveSubnetIDs(ng)
+		if err != nil {
+			return nil, err
+		}
+		targets := make([]perAZTarget, len(ids))
+		for i, id := range ids {
+			targets[i] = perAZTarget{suffix: resourceSuffix(ng.Subnets[i]), subnetID: id}
+		}
+		return targets, nil
+	}
+
+	subnets := n.clusterSpec.VPC.Subnets[ng.SubnetTopology()]
+	targets := make([]perAZTarget, len(ng.AvailabilityZones))
+	for i, az := range ng.AvailabilityZones {
+		subnet, ok := subnets[az]
+		if !ok {
+			return nil, fmt.Errorf("VPC doesn't have subnets in %s (subnets=%#v AZs=%#v)", az, subnets, ng.AvailabilityZones)
+		}
+		targets[i] = perAZTarget{suffix: resourceSuffixForAZ(az), subnetID: subnet.ID, az: az}
+	}
+	return targets, nil
+}
+
+// addResourcesForNodeGroupPerAZ renders one launch configuration and one ASG per AZ (or explicit
+// subnet) requested for the nodegroup, instead of a single ASG spanning all of them. This is the
+// recommended pattern for EBS-backed workloads, as it lets cluster-autoscaler balance pods across
+// single-AZ groups.
+func (n *NodeGroupResourceSet) addResourcesForNodeGroupPerAZ(ng *api.NodeGroup, suffix string, ctx *nodeGroupContext) error {
+	targets, err := n.resolvePerAZTargets(ng)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		azSuffix := suffix + target.suffix
+
+		lc := &gfn.AWSAutoScalingLaunchConfiguration{
+			IamInstanceProfile: ctx.instanceProfile,
+			SecurityGroups:     ctx.securityGroups,
+			ImageId:            gfn.NewString(ng.AMI),
+			InstanceType:       gfn.NewString(ng.InstanceType),
+			UserData:           ctx.userData,
+		}
+		if ng.AllowSSH {
+			lc.KeyName = gfn.NewString(ng.SSHPublicKeyName)
+		}
+		if ng.PrivateNetworking {
+			lc.AssociatePublicIpAddress = gfn.False()
+		} else {
+			lc.AssociatePublicIpAddress = gfn.True()
+		}
+		blockDeviceMappings, err := makeLaunchConfigBlockDeviceMappings(ng)
+		if err != nil {
+			return err
+		}
+		lc.BlockDeviceMappings = blockDeviceMappings
+
+		refLC := n.newResource("NodeLaunchConfig"+azSuffix, lc)
+
+		tags := n.makeNodeGroupTags(ng)
+		if target.az != "" {
+			tags = append(tags, map[string]interface{}{
+				"Key":               "k8s.io/cluster-autoscaler/node-template/label/topology.kubernetes.io/zone",
+				"Value":             target.az,
+				"PropagateAtLaunch": "true",
+			})
+		}
+
+		asgName := "NodeGroup" + azSuffix
+		n.newResource(asgName, &awsCloudFormationResource{
+			Type: "AWS::AutoScaling::AutoScalingGroup",
+			Properties: map[string]interface{}{
+				"LaunchConfigurationName": refLC,
+				"DesiredCapacity":         fmt.Sprintf("%d", ng.DesiredCapacity),
+				"MinSize":                 fmt.Sprintf("%d", ng.MinSize),
+				"MaxSize":                 fmt.Sprintf("%d", ng.MaxSize),
+				"VPCZoneIdentifier":       []interface{}{target.subnetID},
+				"Tags":                    tags,
+			},
+			UpdatePolicy: map[string]map[string]string{
+				"AutoScalingRollingUpdate": {
+					"MinInstancesInService": "1",
+					"MaxBatchSize":          "1",
+				},
 			},
+		})
+
+		n.rs.defineOutputWithoutCollector("NodeGroupASGName"+azSuffix, gfn.MakeRef(asgName), false)
+	}
+
+	return nil
+}
+
+// resourceSuffixForAZ turns an AZ name (e.g. "us-west-2a") into a CloudFormation logical ID safe
+// suffix (e.g. "UsWest2a"), as logical IDs may only contain alphanumeric characters.
+func resourceSuffixForAZ(az string) string {
+	return resourceSuffix(az)
+}
+
+// resourceSuffix turns an arbitrary nodegroup or AZ name into a CloudFormation logical ID safe
+// suffix by title-casing each `-`-separated part and dropping the separators, since logical IDs
+// may only contain alphanumeric characters.
+func resourceSuffix(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '-'
+	})
+	for i, part := range parts {
+		parts[i] = strings.Title(part)
+	}
+	return strings.Join(parts, "")
+}
+
+// checkForDuplicateSuffixes errors out if two nodegroups in the set would resolve to the same
+// resourceSuffix (e.g. "my-ng" and "myNg" both become "MyNg"), which would otherwise have their
+// CloudFormation resources silently overwrite one another.
+func checkForDuplicateSuffixes(specs []*api.NodeGroup) error {
+	seen := make(map[string]string, len(specs))
+	for _, ng := range specs {
+		suffix := resourceSuffix(ng.Name)
+		if other, ok := seen[suffix]; ok {
+			return fmt.Errorf("nodegroups %q and %q both resolve to the same CloudFormation logical ID suffix %q; nodegroup names must be distinct once `-` separators are dropped", other, ng.Name, suffix)
 		}
+		seen[suffix] = ng.Name
 	}
+	return nil
+}
+
+// makeNodeGroupTags returns the ASG tags common to every nodegroup, including the
+// cluster-autoscaler tags used to identify the ASG as scalable
+func (n *NodeGroupResourceSet) makeNodeGroupTags(ng *api.NodeGroup) []map[string]interface{} {
 	tags := []map[string]interface{}{
 		{
 			"Key":               "Name",
-			"Value":             fmt.Sprintf("%s-%s-Node", n.clusterSpec.Metadata.Name, n.nodeGroupName),
+			"Value":             fmt.Sprintf("%s-%s-Node", n.clusterSpec.Metadata.Name, ng.Name),
 			"PropagateAtLaunch": "true",
 		},
 		{
@@ -165,7 +933,7 @@ func (n *NodeGroupResourceSet) addResourcesForNodeGroup() error {
 			"PropagateAtLaunch": "true",
 		},
 	}
-	if v := n.spec.IAM.WithAddonPolicies.AutoScaler; v != nil && *v {
+	if v := ng.IAM.WithAddonPolicies.AutoScaler; v != nil && *v {
 		tags = append(tags,
 			map[string]interface{}{
 				"Key":               "k8s.io/cluster-autoscaler/enabled",
@@ -178,29 +946,46 @@ func (n *NodeGroupResourceSet) addResourcesForNodeGroup() error {
 				"PropagateAtLaunch": "true",
 			},
 		)
+
+		// let cluster-autoscaler size a nodegroup that is currently at zero without having to
+		// launch a probe instance first; nodebootstrap.NewUserData sets the matching
+		// --node-labels/--register-with-taints kubelet flags so the real nodes match what is
+		// advertised here
+		for key, value := range ng.Labels {
+			tags = append(tags, map[string]interface{}{
+				"Key":               "k8s.io/cluster-autoscaler/node-template/label/" + key,
+				"Value":             value,
+				"PropagateAtLaunch": "true",
+			})
+		}
+		for key, taint := range ng.Taints {
+			tags = append(tags, map[string]interface{}{
+				"Key":               "k8s.io/cluster-autoscaler/node-template/taint/" + key,
+				"Value":             taint,
+				"PropagateAtLaunch": "true",
+			})
+		}
 	}
-	n.newResource("NodeGroup", &awsCloudFormationResource{
-		Type: "AWS::AutoScaling::AutoScalingGroup",
-		Properties: map[string]interface{}{
-			"LaunchConfigurationName": refLC,
-			"DesiredCapacity":         fmt.Sprintf("%d", n.spec.DesiredCapacity),
-			"MinSize":                 fmt.Sprintf("%d", n.spec.MinSize),
-			"MaxSize":                 fmt.Sprintf("%d", n.spec.MaxSize),
-			"VPCZoneIdentifier":       vpcZoneIdentifier,
-			"Tags":                    tags,
-		},
-		UpdatePolicy: map[string]map[string]string{
-			"AutoScalingRollingUpdate": {
-				"MinInstancesInService": "1",
-				"MaxBatchSize":          "1",
-			},
-		},
-	})
+	return tags
+}
 
-	return nil
+// makeNodeGroupTagsWithoutPropagateAtLaunch returns the same tags as makeNodeGroupTags but in the
+// plain `Key`/`Value` shape expected by resources (such as launch template tag specifications) that
+// don't understand `PropagateAtLaunch`
+func (n *NodeGroupResourceSet) makeNodeGroupTagsWithoutPropagateAtLaunch(ng *api.NodeGroup) []map[string]interface{} {
+	tags := n.makeNodeGroupTags(ng)
+	plain := make([]map[string]interface{}, len(tags))
+	for i, tag := range tags {
+		plain[i] = map[string]interface{}{
+			"Key":   tag["Key"],
+			"Value": tag["Value"],
+		}
+	}
+	return plain
 }
 
-// GetAllOutputs collects all outputs of the node group
+// GetAllOutputs delegates to the resource set; no output is read back into a Go value, as all of
+// them are defined without a collector
 func (n *NodeGroupResourceSet) GetAllOutputs(stack cfn.Stack) error {
 	return n.rs.GetAllOutputs(stack)
 }
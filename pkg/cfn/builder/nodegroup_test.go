@@ -0,0 +1,226 @@
+package builder
+
+import (
+	"testing"
+
+	gfn "github.com/awslabs/goformation/cloudformation"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha4"
+	"github.com/weaveworks/eksctl/pkg/cfn/outputs"
+)
+
+func newTestResourceSet() *NodeGroupResourceSet {
+	return &NodeGroupResourceSet{
+		rs:          newResourceSet(),
+		clusterSpec: &api.ClusterConfig{Metadata: &api.ClusterMeta{Name: "test"}},
+		sgCache:     make(map[string][]*gfn.Value),
+		iamCache:    make(map[string]*gfn.Value),
+	}
+}
+
+func TestAddResourcesForIAM_CreatesTheRoleItProfileReferences(t *testing.T) {
+	n := newTestResourceSet()
+	ng := &api.NodeGroup{Name: "a"}
+	suffix := resourceSuffix(ng.Name)
+
+	n.addResourcesForIAM(ng, suffix)
+
+	if _, ok := n.rs.template.Resources["NodeInstanceRole"+suffix]; !ok {
+		t.Fatalf("expected a NodeInstanceRole%s resource backing the instance profile's Ref", suffix)
+	}
+}
+
+func TestAddResourcesForIAM_DeduplicatesIdenticalSpecs(t *testing.T) {
+	n := newTestResourceSet()
+
+	ngA := &api.NodeGroup{Name: "a"}
+	ngB := &api.NodeGroup{Name: "b"}
+
+	profileA := n.addResourcesForIAM(ngA, resourceSuffix(ngA.Name))
+	profileB := n.addResourcesForIAM(ngB, resourceSuffix(ngB.Name))
+
+	if profileA != profileB {
+		t.Fatalf("expected nodegroups with identical IAM specs to share an instance profile, got distinct values %#v vs %#v", profileA, profileB)
+	}
+
+	ngC := &api.NodeGroup{Name: "c", IAM: api.NodeGroupIAM{WithAddonPolicies: api.NodeGroupIAMAddonPolicies{AutoScaler: boolPtr(true)}}}
+	profileC := n.addResourcesForIAM(ngC, resourceSuffix(ngC.Name))
+	if profileC == profileA {
+		t.Fatalf("expected a nodegroup with a different IAM spec to get its own instance profile")
+	}
+
+	// ngD sets AutoScaler to the same value as ngC, but via a distinct *bool allocation; they must
+	// still dedup to the same instance profile as each other.
+	ngD := &api.NodeGroup{Name: "d", IAM: api.NodeGroupIAM{WithAddonPolicies: api.NodeGroupIAMAddonPolicies{AutoScaler: boolPtr(true)}}}
+	profileD := n.addResourcesForIAM(ngD, resourceSuffix(ngD.Name))
+	if profileD != profileC {
+		t.Fatalf("expected two nodegroups with equal (but distinctly allocated) *bool IAM fields to share an instance profile, got distinct values %#v vs %#v", profileC, profileD)
+	}
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+func TestMakeLaunchTemplateData_NeverMixesTopLevelAndNetworkInterfaceSecurityGroups(t *testing.T) {
+	n := newTestResourceSet()
+	ctx := &nodeGroupContext{
+		userData:           gfn.NewString(""),
+		instanceProfile:    gfn.NewString("profile-name"),
+		instanceProfileArn: gfn.MakeFnGetAttString("NodeInstanceProfile.Arn"),
+		securityGroups:     []*gfn.Value{gfn.NewString("sg-1")},
+	}
+
+	for _, private := range []bool{true, false} {
+		ng := &api.NodeGroup{PrivateNetworking: private}
+		ltData, err := n.makeLaunchTemplateData(ng, ctx, "m5.large")
+		if err != nil {
+			t.Fatalf("private=%v: %v", private, err)
+		}
+		if ltData.SecurityGroupIds != nil {
+			t.Fatalf("private=%v: top-level SecurityGroupIds must be unset when NetworkInterfaces is populated, got %#v", private, ltData.SecurityGroupIds)
+		}
+		if len(ltData.NetworkInterfaces) != 1 {
+			t.Fatalf("private=%v: expected exactly one NetworkInterface, got %d", private, len(ltData.NetworkInterfaces))
+		}
+		if len(ltData.NetworkInterfaces[0].Groups) != 1 {
+			t.Fatalf("private=%v: expected security groups to be attached via the NetworkInterface", private)
+		}
+		if ltData.IamInstanceProfile.Arn != ctx.instanceProfileArn {
+			t.Fatalf("private=%v: expected IamInstanceProfile.Arn to use the GetAtt ARN, not the Ref name", private)
+		}
+	}
+}
+
+func TestAddAllResources_ErrorsOnCollidingResourceSuffixes(t *testing.T) {
+	n := NewNodeGroupResourceSet(nil, &api.ClusterConfig{Metadata: &api.ClusterMeta{Name: "test"}}, "eksctl-test-cluster", []*api.NodeGroup{
+		{Name: "my-ng", DesiredCapacity: 1},
+		{Name: "myNg", DesiredCapacity: 1},
+	})
+
+	err := n.AddAllResources()
+	if err == nil {
+		t.Fatalf("expected an error for two nodegroup names that collide once `-` separators are dropped")
+	}
+}
+
+func TestResolvePerAZTargets_PrefersExplicitSubnetsOverAZs(t *testing.T) {
+	n := newTestResourceSet()
+	n.clusterSpec.VPC = &api.ClusterVPC{
+		Subnets: map[api.SubnetTopology]map[string]api.Network{
+			api.SubnetTopologyPublic: {
+				"us-west-2a": {ID: "subnet-aaaa"},
+				"us-west-2b": {ID: "subnet-bbbb"},
+			},
+		},
+	}
+
+	ng := &api.NodeGroup{Subnets: []string{"subnet-cccc", "us-west-2b"}, AvailabilityZones: []string{"us-west-2a"}}
+	targets, err := n.resolvePerAZTargets(ng)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 2 {
+		t.Fatalf("expected 2 targets, got %d", len(targets))
+	}
+	if targets[0].subnetID != "subnet-cccc" || targets[1].subnetID != "subnet-bbbb" {
+		t.Fatalf("unexpected resolved subnet IDs: %#v", targets)
+	}
+}
+
+func TestAddResourcesForSecurityGroups_RecordsAttachedVsOwned(t *testing.T) {
+	n := newTestResourceSet()
+	n.clusterStackName = "eksctl-test-cluster"
+	n.vpc = gfn.NewString("vpc-1234")
+
+	attached := &api.NodeGroup{Name: "attached", SecurityGroups: api.NodeGroupSecurityGroups{AttachIDs: []string{"sg-aaaa", "sg-bbbb"}}}
+	n.addResourcesForSecurityGroups(attached, resourceSuffix(attached.Name))
+	if _, ok := n.rs.outputs[outputs.NodeGroupSecurityGroupAttachIDs+resourceSuffix(attached.Name)]; !ok {
+		t.Fatalf("expected an attach-IDs output for a nodegroup using pre-existing security groups")
+	}
+	if _, ok := n.rs.outputs[outputs.NodeGroupSecurityGroupLocalID+resourceSuffix(attached.Name)]; ok {
+		t.Fatalf("didn't expect a local-SG output for a nodegroup that only attaches pre-existing security groups")
+	}
+
+	owned := &api.NodeGroup{Name: "owned", SecurityGroups: api.NodeGroupSecurityGroups{WithLocal: true}}
+	n.addResourcesForSecurityGroups(owned, resourceSuffix(owned.Name))
+	if _, ok := n.rs.outputs[outputs.NodeGroupSecurityGroupLocalID+resourceSuffix(owned.Name)]; !ok {
+		t.Fatalf("expected a local-SG output for a nodegroup that owns its security group")
+	}
+	if _, ok := n.rs.outputs[outputs.NodeGroupSecurityGroupAttachIDs+resourceSuffix(owned.Name)]; ok {
+		t.Fatalf("didn't expect an attach-IDs output for a nodegroup that owns its security group")
+	}
+}
+
+func TestResolveFleetSubnetIDs(t *testing.T) {
+	n := newTestResourceSet()
+	n.clusterSpec.VPC = &api.ClusterVPC{
+		Subnets: map[api.SubnetTopology]map[string]api.Network{
+			api.SubnetTopologyPublic: {
+				"us-west-2a": {ID: "subnet-aaaa"},
+				"us-west-2b": {ID: "subnet-bbbb"},
+			},
+		},
+	}
+
+	ids, err := n.resolveFleetSubnetIDs(&api.NodeGroup{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("expected fleet to fall back to every subnet in the topology when none are given, got %#v", ids)
+	}
+
+	ids, err = n.resolveFleetSubnetIDs(&api.NodeGroup{AvailabilityZones: []string{"us-west-2a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0] != "subnet-aaaa" {
+		t.Fatalf("expected fleet to honor explicit AZs, got %#v", ids)
+	}
+}
+
+func TestAddResourcesForNodeGroupWithFleet_SetsSubnetIDAndCapacityType(t *testing.T) {
+	n := newTestResourceSet()
+	n.clusterStackName = "eksctl-test-cluster"
+	n.vpc = gfn.NewString("vpc-1234")
+	n.clusterSpec.VPC = &api.ClusterVPC{
+		Subnets: map[api.SubnetTopology]map[string]api.Network{
+			api.SubnetTopologyPublic: {"us-west-2a": {ID: "subnet-aaaa"}},
+		},
+	}
+
+	strategy := "capacity-optimized"
+	ng := &api.NodeGroup{
+		Name:            "fleet",
+		InstanceType:    "m5.large",
+		MaxSize:         3,
+		DesiredCapacity: 2,
+		Provisioner:     api.NodeGroupProvisionerFleet,
+		FleetConfig:     &api.NodeGroupFleetConfig{SpotAllocationStrategy: &strategy},
+	}
+	ctx := &nodeGroupContext{
+		userData:           gfn.NewString(""),
+		instanceProfile:    gfn.NewString("profile-name"),
+		instanceProfileArn: gfn.MakeFnGetAttString("NodeInstanceProfile.Arn"),
+	}
+
+	if err := n.addResourcesForNodeGroupWithFleet(ng, resourceSuffix(ng.Name), ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	fleet, ok := n.rs.template.Resources["NodeFleet"+resourceSuffix(ng.Name)].(*awsCloudFormationResource)
+	if !ok {
+		t.Fatalf("expected a NodeFleet resource to be rendered")
+	}
+	configs := fleet.Properties["LaunchTemplateConfigs"].([]map[string]interface{})
+	overrides := configs[0]["Overrides"].([]map[string]interface{})
+	if len(overrides) != 1 || overrides[0]["SubnetId"] != "subnet-aaaa" {
+		t.Fatalf("expected every override to carry a SubnetId, got %#v", overrides)
+	}
+
+	capacity := fleet.Properties["TargetCapacitySpecification"].(map[string]interface{})
+	if capacity["DefaultTargetCapacityType"] != "spot" {
+		t.Fatalf("expected a SpotAllocationStrategy to default the fleet to spot capacity, got %#v", capacity)
+	}
+}
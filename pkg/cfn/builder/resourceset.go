@@ -0,0 +1,84 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+
+	cfn "github.com/aws/aws-sdk-go/service/cloudformation"
+	gfn "github.com/awslabs/goformation/cloudformation"
+)
+
+const (
+	nodeGroupTemplateDescription = "EKS Managed Nodes"
+	templateDescriptionSuffix    = "[created and managed by eksctl]"
+)
+
+// awsCloudFormationResource is used for resource types goformation doesn't have a typed struct for
+// yet (e.g. AWS::EC2::EC2Fleet), so that they can still be rendered with an UpdatePolicy/Properties
+// shape matching what CloudFormation expects.
+type awsCloudFormationResource struct {
+	Type           string                       `json:"Type"`
+	Properties     map[string]interface{}       `json:"Properties,omitempty"`
+	UpdatePolicy   map[string]map[string]string `json:"UpdatePolicy,omitempty"`
+	DependsOn      []string                     `json:"DependsOn,omitempty"`
+	DeletionPolicy string                       `json:"DeletionPolicy,omitempty"`
+}
+
+// outputValue is an output registered on a resourceSet, optionally exportable to other stacks via
+// Fn::ImportValue.
+type outputValue struct {
+	value      interface{}
+	exportable bool
+}
+
+// resourceSet accumulates the resources and outputs that make up a single CloudFormation template
+type resourceSet struct {
+	template *gfn.Template
+	outputs  map[string]outputValue
+}
+
+func newResourceSet() *resourceSet {
+	return &resourceSet{
+		template: gfn.NewTemplate(),
+		outputs:  make(map[string]outputValue),
+	}
+}
+
+// newResource adds a resource to the set and returns an Fn::Ref to it
+func (r *resourceSet) newResource(name string, resource interface{}) *gfn.Value {
+	r.template.Resources[name] = resource
+	return gfn.MakeRef(name)
+}
+
+// defineOutputWithoutCollector registers an output on the template without wiring it up to be read
+// back into the Go struct once the stack is created; used for outputs that downstream tooling (e.g.
+// `eksctl delete nodegroup`) reads directly off the stack instead.
+func (r *resourceSet) defineOutputWithoutCollector(name string, value interface{}, exportable bool) {
+	r.outputs[name] = outputValue{value: value, exportable: exportable}
+	output := map[string]interface{}{"Value": value}
+	if exportable {
+		output["Export"] = map[string]interface{}{"Name": gfn.MakeFnSubString(fmt.Sprintf("${%s}-%s", gfn.StackName, name))}
+	}
+	r.template.Outputs[name] = output
+}
+
+// renderJSON returns the rendered JSON for the template
+func (r *resourceSet) renderJSON() ([]byte, error) {
+	return json.Marshal(r.template)
+}
+
+// GetAllOutputs walks the given stack's outputs but stores nothing: every output of a resourceSet is
+// defined without a collector, so none is read back into a Go value
+func (r *resourceSet) GetAllOutputs(stack cfn.Stack) error {
+	for _, o := range stack.Outputs {
+		if _, ok := r.outputs[*o.OutputKey]; !ok {
+			continue
+		}
+	}
+	return nil
+}
+
+// makeImportValue returns an Fn::ImportValue referencing an output exported by another stack
+func makeImportValue(stackName, output string) *gfn.Value {
+	return gfn.MakeFnImportValueString(fmt.Sprintf("%s::%s", stackName, output))
+}
@@ -0,0 +1,37 @@
+// Package outputs names the CloudFormation stack outputs shared between the cluster stack and the
+// nodegroup stack(s) that import values from it.
+package outputs
+
+const (
+	// ClusterVPC is the cluster stack output holding the cluster's VPC ID
+	ClusterVPC = "VPC"
+
+	// ClusterSecurityGroup is the cluster stack output holding the shared control-plane-to-node
+	// security group ID
+	ClusterSecurityGroup = "SecurityGroup"
+
+	// ClusterSubnets is the prefix of the cluster stack outputs holding the comma-separated subnet
+	// IDs for a given topology (public/private), e.g. "SubnetsPublic"/"SubnetsPrivate"
+	ClusterSubnets = "Subnets"
+
+	// NodeGroupFeaturePrivateNetworking is the per-nodegroup output recording whether the nodegroup
+	// was created with private networking enabled
+	NodeGroupFeaturePrivateNetworking = "FeaturePrivateNetworking"
+
+	// NodeGroupFeatureSharedSecurityGroup is the per-nodegroup output recording whether the
+	// cluster's shared security group was attached to the nodegroup
+	NodeGroupFeatureSharedSecurityGroup = "FeatureSharedSecurityGroup"
+
+	// NodeGroupFeatureLocalSecurityGroup is the per-nodegroup output recording whether eksctl
+	// created and owns a security group local to the nodegroup
+	NodeGroupFeatureLocalSecurityGroup = "FeatureLocalSecurityGroup"
+
+	// NodeGroupSecurityGroupAttachIDs is the per-nodegroup output listing the pre-existing,
+	// user-supplied security group IDs attached to the nodegroup; eksctl doesn't own these and
+	// `eksctl delete nodegroup` must never try to delete them
+	NodeGroupSecurityGroupAttachIDs = "SecurityGroupAttachIDs"
+
+	// NodeGroupSecurityGroupLocalID is the per-nodegroup output holding the ID of the security
+	// group eksctl created and owns for the nodegroup, if any
+	NodeGroupSecurityGroupLocalID = "SecurityGroupLocalID"
+)
@@ -0,0 +1,110 @@
+// Package nodebootstrap renders the cloud-init userdata that bootstraps a nodegroup's instances and
+// joins them to the cluster.
+package nodebootstrap
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha4"
+)
+
+const defaultFilesystemType = "ext4"
+
+var bootstrapTemplate = template.Must(template.New("userdata").Parse(`#!/bin/bash
+set -o xtrace
+/etc/eks/bootstrap.sh '{{ .ClusterName }}'{{ if .KubeletExtraArgs }} --kubelet-extra-args '{{ .KubeletExtraArgs }}'{{ end }}
+{{- range .VolumeMounts }}
+mkfs -t {{ .FilesystemType }} {{ .Device }}
+mkdir -p {{ .MountPath }}
+mount {{ .Device }} {{ .MountPath }}
+echo '{{ .Device }} {{ .MountPath }} {{ .FilesystemType }} defaults,nofail 0 2' >> /etc/fstab
+{{- end }}
+`))
+
+type bootstrapParams struct {
+	ClusterName      string
+	KubeletExtraArgs string
+	VolumeMounts     []volumeMountParams
+}
+
+type volumeMountParams struct {
+	Device         string
+	MountPath      string
+	FilesystemType string
+}
+
+// NewUserData renders the cloud-init userdata for the given nodegroup, joining it to the cluster
+// described by spec. When the nodegroup declares Labels/Taints, the matching
+// --node-labels/--register-with-taints kubelet flags are passed through bootstrap.sh so the real
+// node objects end up with the labels/taints cluster-autoscaler was told to expect via the ASG's
+// node-template tags. Additional volumes with a MountPath set are formatted and mounted there.
+func NewUserData(spec *api.ClusterConfig, ng *api.NodeGroup) (string, error) {
+	var buf bytes.Buffer
+	params := bootstrapParams{
+		ClusterName:      spec.Metadata.Name,
+		KubeletExtraArgs: kubeletExtraArgs(ng),
+		VolumeMounts:     volumeMounts(ng),
+	}
+	if err := bootstrapTemplate.Execute(&buf, params); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// kubeletExtraArgs builds the --node-labels/--register-with-taints kubelet flags from the
+// nodegroup's Labels/Taints, in deterministic (sorted by key) order.
+func kubeletExtraArgs(ng *api.NodeGroup) string {
+	var args []string
+
+	if len(ng.Labels) > 0 {
+		var labels []string
+		for _, key := range sortedKeys(ng.Labels) {
+			labels = append(labels, fmt.Sprintf("%s=%s", key, ng.Labels[key]))
+		}
+		args = append(args, "--node-labels="+strings.Join(labels, ","))
+	}
+
+	if len(ng.Taints) > 0 {
+		var taints []string
+		for _, key := range sortedKeys(ng.Taints) {
+			taints = append(taints, fmt.Sprintf("%s=%s", key, ng.Taints[key]))
+		}
+		args = append(args, "--register-with-taints="+strings.Join(taints, ","))
+	}
+
+	return strings.Join(args, " ")
+}
+
+// volumeMounts returns the additional volumes that should be formatted and mounted, i.e. those
+// with a MountPath set; volumes without one are attached but left untouched by userdata.
+func volumeMounts(ng *api.NodeGroup) []volumeMountParams {
+	var mounts []volumeMountParams
+	for _, vol := range ng.AdditionalVolumes {
+		if vol.MountPath == "" {
+			continue
+		}
+		filesystemType := vol.FilesystemType
+		if filesystemType == "" {
+			filesystemType = defaultFilesystemType
+		}
+		mounts = append(mounts, volumeMountParams{
+			Device:         vol.Device,
+			MountPath:      vol.MountPath,
+			FilesystemType: filesystemType,
+		})
+	}
+	return mounts
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
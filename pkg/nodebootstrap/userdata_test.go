@@ -0,0 +1,65 @@
+package nodebootstrap
+
+import (
+	"strings"
+	"testing"
+
+	api "github.com/weaveworks/eksctl/pkg/apis/eksctl.io/v1alpha4"
+)
+
+func TestNewUserData_EmitsLabelsAndTaintsAsKubeletFlags(t *testing.T) {
+	spec := &api.ClusterConfig{Metadata: &api.ClusterMeta{Name: "test-cluster"}}
+	ng := &api.NodeGroup{
+		Labels: map[string]string{"workload": "batch"},
+		Taints: map[string]string{"dedicated": "batch:NoSchedule"},
+	}
+
+	userData, err := NewUserData(spec, ng)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(userData, "--node-labels=workload=batch") {
+		t.Fatalf("expected userdata to set --node-labels, got:\n%s", userData)
+	}
+	if !strings.Contains(userData, "--register-with-taints=dedicated=batch:NoSchedule") {
+		t.Fatalf("expected userdata to set --register-with-taints, got:\n%s", userData)
+	}
+}
+
+func TestNewUserData_FormatsAndMountsAdditionalVolumesWithMountPath(t *testing.T) {
+	spec := &api.ClusterConfig{Metadata: &api.ClusterMeta{Name: "test-cluster"}}
+	ng := &api.NodeGroup{
+		AdditionalVolumes: []api.NodeGroupVolume{
+			{Device: "/dev/xvdb", MountPath: "/var/lib/docker"},
+			{Device: "/dev/xvdc", MountPath: "/var/lib/kubelet", FilesystemType: "xfs"},
+			{Device: "/dev/xvdd"},
+		},
+	}
+
+	userData, err := NewUserData(spec, ng)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(userData, "mkfs -t ext4 /dev/xvdb") || !strings.Contains(userData, "mount /dev/xvdb /var/lib/docker") {
+		t.Fatalf("expected userdata to format and mount /dev/xvdb with the default filesystem type, got:\n%s", userData)
+	}
+	if !strings.Contains(userData, "mkfs -t xfs /dev/xvdc") || !strings.Contains(userData, "mount /dev/xvdc /var/lib/kubelet") {
+		t.Fatalf("expected userdata to format and mount /dev/xvdc with its explicit filesystem type, got:\n%s", userData)
+	}
+	if strings.Contains(userData, "/dev/xvdd") {
+		t.Fatalf("expected a volume without a MountPath to be left unformatted and unmounted, got:\n%s", userData)
+	}
+}
+
+func TestNewUserData_OmitsKubeletExtraArgsWhenNoLabelsOrTaints(t *testing.T) {
+	spec := &api.ClusterConfig{Metadata: &api.ClusterMeta{Name: "test-cluster"}}
+	userData, err := NewUserData(spec, &api.NodeGroup{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(userData, "--kubelet-extra-args") {
+		t.Fatalf("expected no --kubelet-extra-args when the nodegroup has no labels/taints, got:\n%s", userData)
+	}
+}